@@ -0,0 +1,138 @@
+// Package beaglebone registers the BeagleBone Black's P8/P9 headers with
+// the gpio package. Import it for its side effect:
+//
+//	import _ "github.com/gravitymir/gpio_v1/board/beaglebone"
+package beaglebone
+
+import "github.com/gravitymir/gpio_v1/gpio"
+
+func init() {
+	gpio.RegisterBoard(board)
+}
+
+func fixed(alias string) gpio.PinDesc {
+	return gpio.PinDesc{Aliases: []string{alias}, Line: -1}
+}
+
+func gpioPin(physical string, line int, caps gpio.Capability) gpio.PinDesc {
+	return gpio.PinDesc{
+		Aliases: []string{physical},
+		Line:    line,
+		Caps:    gpio.CapGPIO | caps,
+	}
+}
+
+// gpioPWMPin is gpioPin plus the eHRPWM/eCAP pwmchip/channel the line is
+// wired to when the matching device tree overlay is loaded.
+func gpioPWMPin(physical string, line, pwmChip, pwmChannel int, caps gpio.Capability) gpio.PinDesc {
+	pd := gpioPin(physical, line, caps|gpio.CapPWM)
+	pd.PWMChip = pwmChip
+	pd.PWMChannel = pwmChannel
+	return pd
+}
+
+// analogPin describes an AIN-only header position: it has no GPIO line, only
+// an ADC channel on the SoC's single IIO device.
+func analogPin(physical string, adcChannel int) gpio.PinDesc {
+	return gpio.PinDesc{
+		Aliases:    []string{physical},
+		Line:       -1,
+		Caps:       gpio.CapADC,
+		ADCDevice:  0,
+		ADCChannel: adcChannel,
+	}
+}
+
+// board is the BeagleBone Black's P8 and P9 headers, keyed by their
+// silkscreen labels (e.g. "P9_11"). Line numbers are the usual
+// bank*32+offset Linux GPIO numbers for gpiochip0-3.
+var board = &gpio.Board{
+	Name:   "BeagleBone Black",
+	Models: []string{"BeagleBoard.org BeagleBone Black", "TI AM335x BeagleBone Black"},
+	Pins: []gpio.PinDesc{
+		fixed("P9_1"),  // Ground
+		fixed("P9_2"),  // Ground
+		fixed("P9_3"),  // 3.3V
+		fixed("P9_4"),  // 3.3V
+		fixed("P9_5"),  // 5V
+		fixed("P9_6"),  // 5V
+		fixed("P9_7"),  // 5V (SYS)
+		fixed("P9_8"),  // 5V (SYS)
+		fixed("P9_9"),  // PWR_BUT
+		fixed("P9_10"), // RESET
+		gpioPin("P9_11", 30, 0),
+		gpioPin("P9_12", 60, 0),
+		gpioPin("P9_13", 31, 0),
+		gpioPWMPin("P9_14", 50, 1, 0, 0),
+		gpioPin("P9_15", 48, 0),
+		gpioPWMPin("P9_16", 51, 1, 1, 0),
+		gpioPin("P9_17", 5, gpio.CapSPI),
+		gpioPin("P9_18", 4, gpio.CapSPI),
+		gpioPWMPin("P9_21", 3, 0, 1, gpio.CapSPI),
+		gpioPWMPin("P9_22", 2, 0, 0, gpio.CapSPI),
+		gpioPin("P9_23", 49, 0),
+		gpioPin("P9_24", 15, gpio.CapUART),
+		gpioPin("P9_25", 117, 0),
+		gpioPin("P9_26", 14, gpio.CapUART),
+		gpioPin("P9_27", 125, 0),
+		gpioPin("P9_28", 123, gpio.CapSPI),
+		gpioPWMPin("P9_29", 121, 2, 1, gpio.CapSPI),
+		gpioPin("P9_30", 122, gpio.CapSPI),
+		gpioPWMPin("P9_31", 120, 2, 0, gpio.CapSPI),
+
+		analogPin("P9_33", 4),
+		analogPin("P9_35", 6),
+		analogPin("P9_36", 5),
+		analogPin("P9_37", 2),
+		analogPin("P9_38", 3),
+		analogPin("P9_39", 0),
+		analogPin("P9_40", 1),
+
+		fixed("P8_1"), // Ground
+		fixed("P8_2"), // Ground
+		gpioPin("P8_3", 38, 0),
+		gpioPin("P8_4", 39, 0),
+		gpioPin("P8_5", 34, 0),
+		gpioPin("P8_6", 35, 0),
+		gpioPin("P8_7", 66, 0),
+		gpioPin("P8_8", 67, 0),
+		gpioPin("P8_9", 69, 0),
+		gpioPin("P8_10", 68, 0),
+		gpioPin("P8_11", 45, 0),
+		gpioPin("P8_12", 44, 0),
+		gpioPWMPin("P8_13", 23, 2, 1, 0),
+		gpioPin("P8_14", 26, 0),
+		gpioPin("P8_15", 47, 0),
+		gpioPin("P8_16", 46, 0),
+		gpioPin("P8_17", 27, 0),
+		gpioPin("P8_18", 65, 0),
+		gpioPWMPin("P8_19", 22, 2, 0, 0),
+		gpioPin("P8_20", 63, 0),
+		gpioPin("P8_21", 62, 0),
+		gpioPin("P8_22", 37, 0),
+		gpioPin("P8_23", 36, 0),
+		gpioPin("P8_24", 33, 0),
+		gpioPin("P8_25", 32, 0),
+		gpioPin("P8_26", 61, 0),
+		gpioPin("P8_27", 86, 0),
+		gpioPin("P8_28", 88, 0),
+		gpioPin("P8_29", 87, 0),
+		gpioPin("P8_30", 89, 0),
+		gpioPin("P8_31", 10, gpio.CapUART),
+		gpioPin("P8_32", 11, gpio.CapUART),
+		gpioPin("P8_33", 9, 0),
+		gpioPWMPin("P8_34", 81, 3, 1, 0),
+		gpioPin("P8_35", 8, 0),
+		gpioPWMPin("P8_36", 80, 3, 0, 0),
+		gpioPin("P8_37", 78, gpio.CapUART),
+		gpioPin("P8_38", 79, gpio.CapUART),
+		gpioPin("P8_39", 76, 0),
+		gpioPin("P8_40", 77, 0),
+		gpioPin("P8_41", 74, 0),
+		gpioPin("P8_42", 75, 0),
+		gpioPin("P8_43", 72, 0),
+		gpioPin("P8_44", 73, 0),
+		gpioPWMPin("P8_45", 70, 3, 0, 0),
+		gpioPWMPin("P8_46", 71, 3, 1, 0),
+	},
+}
@@ -0,0 +1,90 @@
+// Package rpiheader builds the 40-pin header layout shared by every
+// Raspberry Pi from the B+ onward, and by pin-compatible boards such as the
+// UP Board. It's internal because the layout itself isn't a public API;
+// board packages expose it wrapped in a *gpio.Board.
+package rpiheader
+
+import "github.com/gravitymir/gpio_v1/gpio"
+
+// fixed describes a ground, power or other non-GPIO header position.
+func fixed(alias string) gpio.PinDesc {
+	return gpio.PinDesc{Aliases: []string{alias}, Line: -1}
+}
+
+// gpioPin describes a GPIO-capable header position, aliased both by its
+// physical position (e.g. "P1_7") and its Linux GPIO number (e.g. "GPIO4").
+func gpioPin(physical string, line int, caps gpio.Capability) gpio.PinDesc {
+	return gpio.PinDesc{
+		Aliases: []string{physical, gpioAlias(line)},
+		Line:    line,
+		Caps:    gpio.CapGPIO | caps,
+	}
+}
+
+// gpioPWMPin is gpioPin plus the pwmchip/channel the line is wired to when
+// its hardware-PWM overlay is loaded.
+func gpioPWMPin(physical string, line, pwmChip, pwmChannel int, caps gpio.Capability) gpio.PinDesc {
+	pd := gpioPin(physical, line, caps|gpio.CapPWM)
+	pd.PWMChip = pwmChip
+	pd.PWMChannel = pwmChannel
+	return pd
+}
+
+func gpioAlias(line int) string {
+	digits := [...]string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	if line < 10 {
+		return "GPIO" + digits[line]
+	}
+	return "GPIO" + digits[line/10] + digits[line%10]
+}
+
+// Pins returns the 40-pin header, in physical pin order. Pins 8/10 (UART)
+// and 3/5 (I2C) are marked with their usual alternate function even though,
+// like every other GPIO, they can also be driven as plain GPIO. PWM chip
+// and channel numbers assume the standard pwm-2chan device tree overlay and
+// will vary with a custom overlay.
+
+func Pins() []gpio.PinDesc {
+	return []gpio.PinDesc{
+		fixed("P1_1"), // 3.3V
+		fixed("P1_2"), // 5V
+		gpioPin("P1_3", 2, gpio.CapI2C),
+		fixed("P1_4"), // 5V
+		gpioPin("P1_5", 3, gpio.CapI2C),
+		fixed("P1_6"), // Ground
+		gpioPin("P1_7", 4, 0),
+		gpioPin("P1_8", 14, gpio.CapUART),
+		fixed("P1_9"), // Ground
+		gpioPin("P1_10", 15, gpio.CapUART),
+		gpioPin("P1_11", 17, 0),
+		gpioPWMPin("P1_12", 18, 0, 0, 0),
+		gpioPin("P1_13", 27, 0),
+		fixed("P1_14"), // Ground
+		gpioPin("P1_15", 22, 0),
+		gpioPin("P1_16", 23, 0),
+		fixed("P1_17"), // 3.3V
+		gpioPin("P1_18", 24, 0),
+		gpioPin("P1_19", 10, gpio.CapSPI),
+		fixed("P1_20"), // Ground
+		gpioPin("P1_21", 9, gpio.CapSPI),
+		gpioPin("P1_22", 25, 0),
+		gpioPin("P1_23", 11, gpio.CapSPI),
+		gpioPin("P1_24", 8, gpio.CapSPI),
+		fixed("P1_25"), // Ground
+		gpioPin("P1_26", 7, gpio.CapSPI),
+		gpioPin("P1_27", 0, gpio.CapI2C),
+		gpioPin("P1_28", 1, gpio.CapI2C),
+		gpioPin("P1_29", 5, 0),
+		fixed("P1_30"), // Ground
+		gpioPin("P1_31", 6, 0),
+		gpioPWMPin("P1_32", 12, 1, 0, 0),
+		gpioPWMPin("P1_33", 13, 1, 1, 0),
+		fixed("P1_34"), // Ground
+		gpioPWMPin("P1_35", 19, 0, 1, 0),
+		gpioPin("P1_36", 16, 0),
+		gpioPin("P1_37", 26, 0),
+		gpioPin("P1_38", 20, 0),
+		fixed("P1_39"), // Ground
+		gpioPin("P1_40", 21, 0),
+	}
+}
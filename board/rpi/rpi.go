@@ -0,0 +1,18 @@
+// Package rpi registers the Raspberry Pi 40-pin header (models B+ and
+// later) with the gpio package. Import it for its side effect:
+//
+//	import _ "github.com/gravitymir/gpio_v1/board/rpi"
+package rpi
+
+import (
+	"github.com/gravitymir/gpio_v1/board/internal/rpiheader"
+	"github.com/gravitymir/gpio_v1/gpio"
+)
+
+func init() {
+	gpio.RegisterBoard(&gpio.Board{
+		Name:   "Raspberry Pi",
+		Models: []string{"Raspberry Pi"},
+		Pins:   rpiheader.Pins(),
+	})
+}
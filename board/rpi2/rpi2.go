@@ -0,0 +1,19 @@
+// Package rpi2 registers the Raspberry Pi 2's 40-pin header with the gpio
+// package. The header is identical to the original 40-pin Pi header; only
+// the model string differs. Import it for its side effect:
+//
+//	import _ "github.com/gravitymir/gpio_v1/board/rpi2"
+package rpi2
+
+import (
+	"github.com/gravitymir/gpio_v1/board/internal/rpiheader"
+	"github.com/gravitymir/gpio_v1/gpio"
+)
+
+func init() {
+	gpio.RegisterBoard(&gpio.Board{
+		Name:   "Raspberry Pi 2",
+		Models: []string{"Raspberry Pi 2"},
+		Pins:   rpiheader.Pins(),
+	})
+}
@@ -0,0 +1,84 @@
+// Package upboard registers the UP Board's 40-pin header with the gpio
+// package. The header is pin-compatible with the Raspberry Pi's, but the
+// UP Board's Apollo Lake SoC exposes those lines as different Linux GPIO
+// numbers (via the "upboard" pinctrl driver). Import it for its side
+// effect:
+//
+//	import _ "github.com/gravitymir/gpio_v1/board/upboard"
+package upboard
+
+import "github.com/gravitymir/gpio_v1/gpio"
+
+func init() {
+	gpio.RegisterBoard(board)
+}
+
+func fixed(alias string) gpio.PinDesc {
+	return gpio.PinDesc{Aliases: []string{alias}, Line: -1}
+}
+
+func gpioPin(physical, gpioAlias string, line int, caps gpio.Capability) gpio.PinDesc {
+	return gpio.PinDesc{
+		Aliases: []string{physical, gpioAlias},
+		Line:    line,
+		Caps:    gpio.CapGPIO | caps,
+	}
+}
+
+func gpioPWMPin(physical, gpioAlias string, line, pwmChip, pwmChannel int, caps gpio.Capability) gpio.PinDesc {
+	pd := gpioPin(physical, gpioAlias, line, caps|gpio.CapPWM)
+	pd.PWMChip = pwmChip
+	pd.PWMChannel = pwmChannel
+	return pd
+}
+
+// board is the UP Board's 40-pin header. Line numbers are taken from the
+// upboard pinctrl driver's default GPIO base offset (of 216); verify
+// against /sys/kernel/debug/gpio on your specific board revision before
+// relying on them, since the offset has changed across kernel versions.
+var board = &gpio.Board{
+	Name:   "UP Board",
+	Models: []string{"UP-APL", "AAEON UP"},
+	Pins: []gpio.PinDesc{
+		fixed("P1_1"), // 3.3V
+		fixed("P1_2"), // 5V
+		gpioPin("P1_3", "GPIO2", 216+2, gpio.CapI2C),
+		fixed("P1_4"), // 5V
+		gpioPin("P1_5", "GPIO3", 216+3, gpio.CapI2C),
+		fixed("P1_6"), // Ground
+		gpioPin("P1_7", "GPIO4", 216+4, 0),
+		gpioPin("P1_8", "GPIO14", 216+14, gpio.CapUART),
+		fixed("P1_9"), // Ground
+		gpioPin("P1_10", "GPIO15", 216+15, gpio.CapUART),
+		gpioPin("P1_11", "GPIO17", 216+17, 0),
+		gpioPWMPin("P1_12", "GPIO18", 216+18, 0, 0, 0),
+		gpioPin("P1_13", "GPIO27", 216+27, 0),
+		fixed("P1_14"), // Ground
+		gpioPin("P1_15", "GPIO22", 216+22, 0),
+		gpioPin("P1_16", "GPIO23", 216+23, 0),
+		fixed("P1_17"), // 3.3V
+		gpioPin("P1_18", "GPIO24", 216+24, 0),
+		gpioPin("P1_19", "GPIO10", 216+10, gpio.CapSPI),
+		fixed("P1_20"), // Ground
+		gpioPin("P1_21", "GPIO9", 216+9, gpio.CapSPI),
+		gpioPin("P1_22", "GPIO25", 216+25, 0),
+		gpioPin("P1_23", "GPIO11", 216+11, gpio.CapSPI),
+		gpioPin("P1_24", "GPIO8", 216+8, gpio.CapSPI),
+		fixed("P1_25"), // Ground
+		gpioPin("P1_26", "GPIO7", 216+7, gpio.CapSPI),
+		gpioPin("P1_27", "GPIO0", 216+0, gpio.CapI2C),
+		gpioPin("P1_28", "GPIO1", 216+1, gpio.CapI2C),
+		gpioPin("P1_29", "GPIO5", 216+5, 0),
+		fixed("P1_30"), // Ground
+		gpioPin("P1_31", "GPIO6", 216+6, 0),
+		gpioPWMPin("P1_32", "GPIO12", 216+12, 1, 0, 0),
+		gpioPWMPin("P1_33", "GPIO13", 216+13, 1, 1, 0),
+		fixed("P1_34"), // Ground
+		gpioPWMPin("P1_35", "GPIO19", 216+19, 0, 1, 0),
+		gpioPin("P1_36", "GPIO16", 216+16, 0),
+		gpioPin("P1_37", "GPIO26", 216+26, 0),
+		gpioPin("P1_38", "GPIO20", 216+20, 0),
+		fixed("P1_39"), // Ground
+		gpioPin("P1_40", "GPIO21", 216+21, 0),
+	},
+}
@@ -0,0 +1,95 @@
+package gpio
+
+// Capability is a bitmask of the buses usable on a header pin.
+type Capability uint
+
+const (
+	CapGPIO Capability = 1 << iota
+	CapI2C
+	CapSPI
+	CapPWM
+	CapUART
+	CapADC
+)
+
+// Has reports whether c includes every capability in want.
+func (c Capability) Has(want Capability) bool {
+	return c&want == want
+}
+
+// PinDesc describes a single header pin on a board.
+type PinDesc struct {
+	// Aliases lists every name this pin can be opened by, e.g.
+	// "P1_7", "GPIO4". OpenPinName matches against all of them.
+	Aliases []string
+	// Line is the Linux GPIO line number wired to this pin, or -1 for
+	// header positions that aren't GPIO (power, ground, ID EEPROM, ...).
+	Line int
+	// Caps lists the buses usable on this pin.
+	Caps Capability
+
+	// PWMChip and PWMChannel identify the /sys/class/pwm/pwmchipN channel
+	// driven by this pin. Valid only when Caps.Has(CapPWM).
+	PWMChip    int
+	PWMChannel int
+
+	// ADCDevice and ADCChannel identify the
+	// /sys/bus/iio/devices/iio:deviceN channel read by this pin. Valid
+	// only when Caps.Has(CapADC).
+	ADCDevice  int
+	ADCChannel int
+}
+
+// Board describes a single-board computer's header layout.
+type Board struct {
+	// Name identifies the board, e.g. "Raspberry Pi".
+	Name string
+	// Models lists the substrings of /proc/cpuinfo's "Model" field or
+	// /proc/device-tree/model that identify this board.
+	Models []string
+	// Pins is the board's header, in physical pin order.
+	Pins []PinDesc
+}
+
+// find returns the PinDesc whose Aliases contains name.
+func (b *Board) find(name string) (PinDesc, bool) {
+	for _, pd := range b.Pins {
+		for _, alias := range pd.Aliases {
+			if alias == name {
+				return pd, true
+			}
+		}
+	}
+	return PinDesc{}, false
+}
+
+// boards is the registry of known boards, populated by board packages
+// (board/rpi, board/rpi2, board/upboard, board/beaglebone, ...) from their
+// init functions.
+var boards []*Board
+
+// RegisterBoard adds b to the set of boards OpenPinName and the
+// auto-detected board can resolve aliases against. It's meant to be called
+// from a board package's init function; importing a board package for its
+// side effect is how a program opts into that board's pin map, e.g.:
+//
+//	import _ "github.com/gravitymir/gpio_v1/board/rpi"
+func RegisterBoard(b *Board) {
+	boards = append(boards, b)
+}
+
+// findPin resolves name against every registered board, preferring the
+// auto-detected one when it has a match.
+func findPin(name string) (PinDesc, bool) {
+	if b := detectedBoard(); b != nil {
+		if pd, ok := b.find(name); ok {
+			return pd, true
+		}
+	}
+	for _, b := range boards {
+		if pd, ok := b.find(name); ok {
+			return pd, true
+		}
+	}
+	return PinDesc{}, false
+}
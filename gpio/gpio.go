@@ -0,0 +1,266 @@
+// Package gpio provides a small, portable API for driving GPIO pins on
+// Linux single-board computers. Two backends are available: the legacy
+// sysfs ABI (/sys/class/gpio) and the newer character-device ABI
+// (/dev/gpiochipN). OpenPin prefers the character device and falls back
+// to sysfs when no gpiochip is present.
+package gpio
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Mode represents the direction of a pin.
+type Mode string
+
+const (
+	ModeInput  Mode = "in"
+	ModeOutput Mode = "out"
+)
+
+// Edge represents which edge(s) of a signal should trigger a watch callback.
+type Edge string
+
+const (
+	EdgeNone    Edge = "none"
+	EdgeRising  Edge = "rising"
+	EdgeFalling Edge = "falling"
+	EdgeBoth    Edge = "both"
+)
+
+// Event describes a single edge observed by BeginWatch.
+type Event struct {
+	// Pin is the number of the pin the event occurred on.
+	Pin int
+	// Edge is the direction that actually triggered: EdgeRising or
+	// EdgeFalling, never EdgeBoth or EdgeNone.
+	Edge Edge
+	// Time is a monotonic timestamp captured as close to the underlying
+	// notification (EpollWait's return, or a poll tick) as possible.
+	Time time.Time
+	// Seq increments once per event delivered to this pin's watch,
+	// starting at 1, so a slow consumer can detect drops.
+	Seq uint64
+}
+
+// IRQEvent is called when a watched edge occurs.
+type IRQEvent func(Event)
+
+// Bias selects a pin's internal resistor configuration.
+type Bias int
+
+const (
+	BiasDisabled Bias = iota
+	BiasPullUp
+	BiasPullDown
+)
+
+// Drive selects how an output pin drives its line.
+type Drive int
+
+const (
+	DrivePushPull Drive = iota
+	DriveOpenDrain
+	DriveOpenSource
+)
+
+// PinConfig describes the full configuration of a pin, beyond the plain
+// input/output direction that OpenPin accepts.
+type PinConfig struct {
+	Mode Mode
+
+	// Bias configures the pin's internal pull resistor. Defaults to
+	// BiasDisabled.
+	Bias Bias
+
+	// Drive configures how an output pin drives its line. Defaults to
+	// DrivePushPull. Ignored for input pins.
+	Drive Drive
+
+	// ActiveLow inverts the pin's logical level: Get/Set/edges are
+	// expressed relative to the inverted signal.
+	ActiveLow bool
+
+	// DebouncePeriod, when non-zero, coalesces edges seen by BeginWatch:
+	// the callback only fires once the line has been stable for this long.
+	DebouncePeriod time.Duration
+
+	// WatchMode selects how BeginWatch delivers edge notifications.
+	// Defaults to WatchAuto.
+	WatchMode WatchMode
+
+	// PollInterval sets the polling period used by WatchPoll and by the
+	// WatchAuto fallback. Defaults to 10ms when zero.
+	PollInterval time.Duration
+}
+
+// WatchMode selects how BeginWatch delivers edge notifications.
+type WatchMode int
+
+const (
+	// WatchAuto uses epoll-driven notifications when the backend and
+	// kernel support them, falling back to polling otherwise.
+	WatchAuto WatchMode = iota
+	// WatchEpoll forces epoll-driven notifications, failing BeginWatch if
+	// they can't be set up.
+	WatchEpoll
+	// WatchPoll forces a goroutine to poll Get at PollInterval and
+	// synthesize edge events from the transitions it observes.
+	WatchPoll
+)
+
+// Pin is the interface implemented by every GPIO backend.
+type Pin interface {
+	// Close releases the pin back to the operating system.
+	Close() error
+
+	// Mode returns the last mode successfully read from the pin.
+	Mode() Mode
+	// SetMode sets the direction of the pin. Errors are available via Err.
+	SetMode(mode Mode)
+
+	// Set drives the pin high.
+	Set()
+	// Clear drives the pin low.
+	Clear()
+	// Get reads the current level of the pin.
+	Get() bool
+
+	// BeginWatch starts calling callback whenever edge occurs.
+	BeginWatch(edge Edge, callback IRQEvent) error
+	// EndWatch stops a watch previously started with BeginWatch.
+	EndWatch() error
+
+	// Events returns a channel carrying every edge event the watch
+	// observes, for consumers that prefer to select on a channel. These are
+	// the events BeginWatch's callback gets when no DebouncePeriod is set;
+	// debouncing applies to the callback only, never to this channel. The
+	// channel is created lazily and has a bounded buffer; if
+	// the consumer falls behind, the oldest buffered event is dropped to
+	// make room for the newest one.
+	Events() <-chan Event
+
+	// Wait is not implemented by either backend: calling it panics rather
+	// than blocking until the pin reaches the given level.
+	Wait(condition bool)
+
+	// Err returns the last error encountered by a non-error-returning method.
+	Err() error
+}
+
+// PWMPin is the interface implemented by a hardware PWM channel.
+type PWMPin interface {
+	// SetPeriod sets the total period of the PWM waveform.
+	SetPeriod(period time.Duration) error
+	// SetDuty sets how much of each period the signal stays high. It must
+	// not exceed the period last passed to SetPeriod.
+	SetDuty(duty time.Duration) error
+	// Enable starts the PWM output.
+	Enable() error
+	// Disable stops the PWM output.
+	Disable() error
+	// Close releases the channel back to the operating system.
+	Close() error
+}
+
+// AnalogPin is the interface implemented by an ADC channel.
+type AnalogPin interface {
+	// Read returns the channel's raw sample.
+	Read() (int, error)
+	// Close releases the channel back to the operating system.
+	Close() error
+}
+
+// OpenPin exports pin n and configures it for the given mode. It prefers the
+// character-device ABI (/dev/gpiochipN) and falls back to the legacy sysfs
+// ABI (/sys/class/gpio) when no gpiochip device is present.
+func OpenPin(n int, mode Mode) (Pin, error) {
+	return OpenPinWithConfig(n, PinConfig{Mode: mode})
+}
+
+// OpenPinWithConfig exports pin n and configures its direction, bias, drive
+// and polarity according to cfg. It prefers the character-device ABI
+// (/dev/gpiochipN) and falls back to the legacy sysfs ABI
+// (/sys/class/gpio) when no gpiochip device is present. n is always a
+// global Linux GPIO number, as reported by board pin maps' PinDesc.Line;
+// the chardev backend resolves it to the owning chip and line offset
+// itself.
+func OpenPinWithConfig(n int, cfg PinConfig) (Pin, error) {
+	if hasChardev() {
+		if p, err := openChardevPin(n, cfg); err == nil {
+			return p, nil
+		}
+		// Fall through to sysfs; some kernels expose gpiochips but still
+		// require sysfs for a particular line (e.g. it's already claimed by
+		// a chardev consumer elsewhere), or n doesn't resolve to any
+		// registered chip.
+	}
+	return openSysfsPin(n, cfg)
+}
+
+// OpenPinName resolves name (e.g. "P1_7" or "GPIO4") against the
+// auto-detected board's header, then behaves like OpenPin on the resolved
+// Linux GPIO line. Import a board/... package for its side effect to make
+// that board's aliases available; see RegisterBoard.
+func OpenPinName(name string, mode Mode) (Pin, error) {
+	return OpenPinNameWithConfig(name, PinConfig{Mode: mode})
+}
+
+// OpenPinNameWithConfig is OpenPinName with the full configurability of
+// OpenPinWithConfig.
+func OpenPinNameWithConfig(name string, cfg PinConfig) (Pin, error) {
+	pd, ok := findPin(name)
+	if !ok {
+		return nil, fmt.Errorf("gpio: unknown pin %q (no matching board registered?)", name)
+	}
+	if !pd.Caps.Has(CapGPIO) {
+		return nil, fmt.Errorf("gpio: pin %q is not a GPIO pin", name)
+	}
+	return OpenPinWithConfig(pd.Line, cfg)
+}
+
+// OpenPWM resolves name against the auto-detected board's header and opens
+// its hardware PWM channel.
+func OpenPWM(name string) (PWMPin, error) {
+	pd, ok := findPin(name)
+	if !ok {
+		return nil, fmt.Errorf("gpio: unknown pin %q (no matching board registered?)", name)
+	}
+	if !pd.Caps.Has(CapPWM) {
+		return nil, fmt.Errorf("gpio: pin %q has no PWM capability", name)
+	}
+	return openSysfsPWM(pd.PWMChip, pd.PWMChannel)
+}
+
+// OpenAnalog resolves name against the auto-detected board's header and
+// opens its ADC channel.
+func OpenAnalog(name string) (AnalogPin, error) {
+	pd, ok := findPin(name)
+	if !ok {
+		return nil, fmt.Errorf("gpio: unknown pin %q (no matching board registered?)", name)
+	}
+	if !pd.Caps.Has(CapADC) {
+		return nil, fmt.Errorf("gpio: pin %q has no analog input capability", name)
+	}
+	return openSysfsAnalog(pd.ADCDevice, pd.ADCChannel)
+}
+
+// PullUp opens pin n as an input with its internal pull-up resistor enabled.
+func PullUp(n int) (Pin, error) {
+	return OpenPinWithConfig(n, PinConfig{Mode: ModeInput, Bias: BiasPullUp})
+}
+
+// PullDown opens pin n as an input with its internal pull-down resistor
+// enabled.
+func PullDown(n int) (Pin, error) {
+	return OpenPinWithConfig(n, PinConfig{Mode: ModeInput, Bias: BiasPullDown})
+}
+
+// hasChardev reports whether the running kernel exposes the character-device
+// GPIO ABI at all, as a cheap gate before resolving a particular line to a
+// chip and offset.
+func hasChardev() bool {
+	chips, _ := filepath.Glob(filepath.Join(gpioChipDevices, "gpiochip*"))
+	return len(chips) > 0
+}
@@ -0,0 +1,36 @@
+package gpio
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+const iioBase = "/sys/bus/iio/devices"
+
+// sysfsAnalogPin is an AnalogPin backed by /sys/bus/iio/devices/iio:deviceN.
+type sysfsAnalogPin struct {
+	rawPath string
+}
+
+// openSysfsAnalog opens the raw-value attribute for channel on IIO device.
+func openSysfsAnalog(device, channel int) (AnalogPin, error) {
+	devicePath := filepath.Join(iioBase, fmt.Sprintf("iio:device%d", device))
+	rawPath := filepath.Join(devicePath, fmt.Sprintf("in_voltage%d_raw", channel))
+	return &sysfsAnalogPin{rawPath: rawPath}, nil
+}
+
+// Read returns the channel's raw sample.
+func (p *sysfsAnalogPin) Read() (int, error) {
+	raw, err := readFile(p.rawPath)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(raw)
+}
+
+// Close is a no-op: IIO raw-value channels aren't exported/unexported like
+// sysfs GPIO or PWM.
+func (p *sysfsAnalogPin) Close() error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package gpio
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+var (
+	boardOnce sync.Once
+	detected  *Board
+)
+
+// detectedBoard identifies the running host by parsing /proc/cpuinfo and
+// /proc/device-tree/model, matching the result against every registered
+// board's Models. The result is cached after the first call.
+func detectedBoard() *Board {
+	boardOnce.Do(func() {
+		detected = matchBoard(hostModel())
+	})
+	return detected
+}
+
+func matchBoard(model string) *Board {
+	if model == "" {
+		return nil
+	}
+	for _, b := range boards {
+		for _, m := range b.Models {
+			if strings.Contains(model, m) {
+				return b
+			}
+		}
+	}
+	return nil
+}
+
+// hostModel returns a human-readable board model string for the running
+// host, or "" if none of the usual sources are available (e.g. not running
+// on a single-board computer).
+func hostModel() string {
+	if buf, err := ioutil.ReadFile("/proc/device-tree/model"); err == nil {
+		return strings.Trim(string(buf), "\x00\n ")
+	}
+	buf, err := ioutil.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(buf), "\n") {
+		if !strings.HasPrefix(line, "Model") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,377 @@
+package gpio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Linux GPIO character-device (gpiod v2) ABI, from <linux/gpio.h>.
+const (
+	gpioMaxNameSize = 32
+	gpioV2LinesMax  = 64
+
+	gpioV2LineFlagUsed         = uint64(1) << 0
+	gpioV2LineFlagActiveLow    = uint64(1) << 1
+	gpioV2LineFlagInput        = uint64(1) << 2
+	gpioV2LineFlagOutput       = uint64(1) << 3
+	gpioV2LineFlagEdgeRising   = uint64(1) << 4
+	gpioV2LineFlagEdgeFalling  = uint64(1) << 5
+	gpioV2LineFlagOpenDrain    = uint64(1) << 6
+	gpioV2LineFlagOpenSource   = uint64(1) << 7
+	gpioV2LineFlagBiasPullUp   = uint64(1) << 8
+	gpioV2LineFlagBiasPullDown = uint64(1) << 9
+	gpioV2LineFlagBiasDisabled = uint64(1) << 10
+)
+
+// gpioV2LineAttribute mirrors struct gpio_v2_line_attribute. Value holds
+// either a flags bitmask, a values bitmask or a debounce period in
+// microseconds, depending on ID.
+type gpioV2LineAttribute struct {
+	ID      uint32
+	Padding uint32
+	Value   uint64
+}
+
+type gpioV2LineConfigAttribute struct {
+	Attr gpioV2LineAttribute
+	Mask uint64
+}
+
+type gpioV2LineConfig struct {
+	Flags    uint64
+	NumAttrs uint32
+	Padding  [5]uint32
+	Attrs    [10]gpioV2LineConfigAttribute
+}
+
+type gpioV2LineRequest struct {
+	Offsets         [gpioV2LinesMax]uint32
+	Consumer        [gpioMaxNameSize]byte
+	Config          gpioV2LineConfig
+	NumLines        uint32
+	EventBufferSize uint32
+	Padding         [5]uint32
+	FD              int32
+}
+
+type gpioV2LineValues struct {
+	Bits uint64
+	Mask uint64
+}
+
+// gpioV2LineEvent mirrors struct gpio_v2_line_event, as delivered by read()
+// on a requested line's FD.
+type gpioV2LineEvent struct {
+	TimestampNS uint64
+	ID          uint32
+	Offset      uint32
+	Seqno       uint32
+	LineSeqno   uint32
+	Padding     [6]uint32
+}
+
+const (
+	gpioV2LineEventRisingEdge  = 1
+	gpioV2LineEventFallingEdge = 2
+)
+
+// Linux ioctl request encoding (_IOC), standard (non-mips/sparc) layout.
+const (
+	iocNrShift   = 0
+	iocTypeShift = iocNrShift + 8
+	iocSizeShift = iocTypeShift + 8
+	iocDirShift  = iocSizeShift + 14
+
+	iocReadWrite = uintptr(3)
+
+	gpioIocMagic = uintptr(0xB4)
+)
+
+func iowr(nr uintptr, size uintptr) uintptr {
+	return (iocReadWrite << iocDirShift) | (gpioIocMagic << iocTypeShift) | (nr << iocNrShift) | (size << iocSizeShift)
+}
+
+var (
+	gpioV2GetLineIOCTL       = iowr(0x07, unsafe.Sizeof(gpioV2LineRequest{}))
+	gpioV2LineSetConfigIOCTL = iowr(0x0D, unsafe.Sizeof(gpioV2LineConfig{}))
+	gpioV2LineGetValuesIOCTL = iowr(0x0E, unsafe.Sizeof(gpioV2LineValues{}))
+	gpioV2LineSetValuesIOCTL = iowr(0x0F, unsafe.Sizeof(gpioV2LineValues{}))
+)
+
+func ioctl(fd uintptr, req uintptr, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// chardevPin is a Pin backed by the GPIO character-device ABI
+// (/dev/gpiochipN + GPIO_V2_* ioctls), the modern replacement for sysfs GPIO.
+type chardevPin struct {
+	eventSink
+
+	number         int
+	lineFD         int
+	cfg            PinConfig // last-applied bias/drive/active-low, kept so SetMode and BeginWatch can rebuild the full flag set instead of discarding it
+	callback       IRQEvent
+	err            error
+	watchMode      WatchMode
+	debouncePeriod time.Duration
+	pollInterval   time.Duration
+	stopPoll       func()
+}
+
+// openChardevPin requests global Linux GPIO line n and configures it
+// according to cfg. n is resolved to the gpiochip device that owns it and
+// the line's offset within that chip via chardevLine, since board pin maps
+// (and the legacy numeric OpenPin signature) hand out global GPIO numbers,
+// not chip-relative offsets.
+func openChardevPin(n int, cfg PinConfig) (Pin, error) {
+	chipPath, offset, err := chardevLine(n)
+	if err != nil {
+		return nil, err
+	}
+
+	chip, err := os.OpenFile(chipPath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer chip.Close()
+
+	var req gpioV2LineRequest
+	req.Offsets[0] = uint32(offset)
+	req.NumLines = 1
+	copy(req.Consumer[:], "gpio")
+	req.Config.Flags = lineConfigFlags(cfg)
+
+	if err := ioctl(chip.Fd(), gpioV2GetLineIOCTL, uintptr(unsafe.Pointer(&req))); err != nil {
+		return nil, fmt.Errorf("gpio: request line %d (offset %d on %s): %w", n, offset, chipPath, err)
+	}
+
+	return &chardevPin{
+		number:         n,
+		lineFD:         int(req.FD),
+		cfg:            cfg,
+		watchMode:      cfg.WatchMode,
+		debouncePeriod: cfg.DebouncePeriod,
+		pollInterval:   cfg.PollInterval,
+	}, nil
+}
+
+// gpioChipDevices is where the kernel exposes one directory per registered
+// gpiochip, named after its /dev/gpiochipN number and carrying "base" (its
+// first global GPIO number) and "ngpio" (how many it owns) attributes.
+const gpioChipDevices = "/sys/bus/gpio/devices"
+
+// chardevLine maps global Linux GPIO number n to the chardev it belongs to
+// and its offset within that chip, by scanning every registered gpiochip's
+// base/ngpio attributes for the one whose range contains n.
+func chardevLine(n int) (chipPath string, offset int, err error) {
+	chips, err := filepath.Glob(filepath.Join(gpioChipDevices, "gpiochip*"))
+	if err != nil {
+		return "", 0, err
+	}
+	for _, dir := range chips {
+		base, err := readIntFile(filepath.Join(dir, "base"))
+		if err != nil {
+			continue
+		}
+		ngpio, err := readIntFile(filepath.Join(dir, "ngpio"))
+		if err != nil {
+			continue
+		}
+		if n < base || n >= base+ngpio {
+			continue
+		}
+		return filepath.Join("/dev", filepath.Base(dir)), n - base, nil
+	}
+	return "", 0, fmt.Errorf("gpio: no gpiochip owns line %d", n)
+}
+
+func readIntFile(path string) (int, error) {
+	s, err := readFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(s)
+}
+
+// lineConfigFlags translates a PinConfig into the GPIO_V2_LINE_FLAG_* bits
+// used by the line-request and set-config ioctls. Each branch sets exactly
+// one gpioV2LineFlag* bit; cross-checked against <linux/gpio.h> now that
+// those constants carry the right bit numbers (see chunk0-1).
+func lineConfigFlags(cfg PinConfig) uint64 {
+	flags := directionFlag(cfg.Mode)
+	if cfg.ActiveLow {
+		flags |= gpioV2LineFlagActiveLow
+	}
+	switch cfg.Bias {
+	case BiasPullUp:
+		flags |= gpioV2LineFlagBiasPullUp
+	case BiasPullDown:
+		flags |= gpioV2LineFlagBiasPullDown
+	case BiasDisabled:
+		flags |= gpioV2LineFlagBiasDisabled
+	}
+	if cfg.Mode == ModeOutput {
+		switch cfg.Drive {
+		case DriveOpenDrain:
+			flags |= gpioV2LineFlagOpenDrain
+		case DriveOpenSource:
+			flags |= gpioV2LineFlagOpenSource
+		}
+	}
+	return flags
+}
+
+func directionFlag(mode Mode) uint64 {
+	if mode == ModeOutput {
+		return gpioV2LineFlagOutput
+	}
+	return gpioV2LineFlagInput
+}
+
+// Close releases the line FD, returning the line to its default state.
+func (p *chardevPin) Close() error {
+	return syscall.Close(p.lineFD)
+}
+
+// Mode reports the direction the line was last configured with.
+func (p *chardevPin) Mode() Mode {
+	return p.cfg.Mode
+}
+
+// SetMode reconfigures the line's direction, preserving the bias, drive and
+// active-low configuration established when the pin was opened.
+func (p *chardevPin) SetMode(mode Mode) {
+	next := p.cfg
+	next.Mode = mode
+	var cfg gpioV2LineConfig
+	cfg.Flags = lineConfigFlags(next)
+	if err := ioctl(uintptr(p.lineFD), gpioV2LineSetConfigIOCTL, uintptr(unsafe.Pointer(&cfg))); err != nil {
+		p.err = err
+		return
+	}
+	p.cfg = next
+}
+
+// Set drives the line high.
+func (p *chardevPin) Set() {
+	p.setValue(true)
+}
+
+// Clear drives the line low.
+func (p *chardevPin) Clear() {
+	p.setValue(false)
+}
+
+func (p *chardevPin) setValue(high bool) {
+	values := gpioV2LineValues{Mask: 1}
+	if high {
+		values.Bits = 1
+	}
+	p.err = ioctl(uintptr(p.lineFD), gpioV2LineSetValuesIOCTL, uintptr(unsafe.Pointer(&values)))
+}
+
+// Get reads the current level of the line.
+func (p *chardevPin) Get() bool {
+	values := gpioV2LineValues{Mask: 1}
+	if err := ioctl(uintptr(p.lineFD), gpioV2LineGetValuesIOCTL, uintptr(unsafe.Pointer(&values))); err != nil {
+		p.err = err
+		return false
+	}
+	return values.Bits&1 != 0
+}
+
+// BeginWatch enables edge detection for edge and registers the line FD with
+// the shared epoll loop; each wake reads a gpio_v2_line_event off the FD.
+func (p *chardevPin) BeginWatch(edge Edge, callback IRQEvent) error {
+	callback = debounce(p.debouncePeriod, callback)
+	deliver := func(ev Event) {
+		ev.Seq = p.nextSeq()
+		p.deliver(callback, ev)
+	}
+
+	if p.watchMode == WatchPoll {
+		p.stopPoll = pollWatch(p.number, edge, p.pollInterval, p.Get, deliver)
+		return nil
+	}
+
+	var cfg gpioV2LineConfig
+	cfg.Flags = lineConfigFlags(p.cfg) | edgeFlags(edge)
+	if err := ioctl(uintptr(p.lineFD), gpioV2LineSetConfigIOCTL, uintptr(unsafe.Pointer(&cfg))); err != nil {
+		if p.watchMode != WatchAuto {
+			return err
+		}
+		p.stopPoll = pollWatch(p.number, edge, p.pollInterval, p.Get, deliver)
+		return nil
+	}
+
+	p.callback = callback
+	if err := registerWatch(p.lineFD, p); err != nil {
+		if p.watchMode != WatchAuto {
+			return err
+		}
+		p.stopPoll = pollWatch(p.number, edge, p.pollInterval, p.Get, deliver)
+	}
+	return nil
+}
+
+func edgeFlags(edge Edge) uint64 {
+	switch edge {
+	case EdgeRising:
+		return gpioV2LineFlagEdgeRising
+	case EdgeFalling:
+		return gpioV2LineFlagEdgeFalling
+	case EdgeBoth:
+		return gpioV2LineFlagEdgeRising | gpioV2LineFlagEdgeFalling
+	default:
+		return 0
+	}
+}
+
+// EndWatch stops watching the line.
+func (p *chardevPin) EndWatch() error {
+	if p.stopPoll != nil {
+		p.stopPoll()
+		p.stopPoll = nil
+		return nil
+	}
+	return unregisterWatch(p.lineFD)
+}
+
+// fire implements edgeWatcher. The line FD is registered edge-triggered, so
+// a single wake can leave more than one gpio_v2_line_event queued (the
+// kernel coalesces a burst of edges into one EPOLLIN); read until it drains,
+// delivering each event, rather than just the first.
+func (p *chardevPin) fire(ts time.Time) {
+	var raw gpioV2LineEvent
+	buf := (*[unsafe.Sizeof(raw)]byte)(unsafe.Pointer(&raw))[:]
+	for {
+		n, err := syscall.Read(p.lineFD, buf)
+		if err != nil || n != int(unsafe.Sizeof(raw)) {
+			return
+		}
+		edge := EdgeFalling
+		if raw.ID == gpioV2LineEventRisingEdge {
+			edge = EdgeRising
+		}
+		p.deliver(p.callback, Event{Pin: p.number, Edge: edge, Time: ts, Seq: p.nextSeq()})
+	}
+}
+
+// Wait is not implemented for the character-device backend; calling it
+// panics.
+func (p *chardevPin) Wait(condition bool) {
+	panic("Wait is not yet implemented!")
+}
+
+// Err returns the last error encountered.
+func (p *chardevPin) Err() error {
+	return p.err
+}
@@ -6,55 +6,16 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 )
 
-//By default, pins 14 and 15 boot to UART mode, so they are going to be ignored for now.
-//We can add them in later as necessary.
-//Pins map UPBoard and RaspberyPi? convert to Linux numbers
-const (
-	PIN_1     = "3.3V"
-	PIN_2     = "5.0V"
-	PIN_3     = 2
-	PIN_4     = "5.0V"
-	PIN_5     = 3
-	PIN_6     = "Ground"
-	PIN_7     = 4
-	PIN_8     = 14
-	PIN_9     = "Ground"
-	PIN_10    = 15
-	PIN_11    = 17
-	PIN_12    = 18
-	PIN_13    = 27
-	PIN_14    = "Ground"
-	PIN_15    = 22
-	PIN_16    = 23
-	PIN_17    = "3.3V"
-	PIN_18    = 24
-	PIN_19    = 10
-	PIN_20    = "Ground"
-	PIN_21    = 9
-	PIN_22    = 25
-	PIN_23    = 11
-	PIN_24    = 8
-	PIN_25    = "Ground"
-	PIN_26    = 7
-	PIN_27    = 0
-	PIN_28    = 1
-	PIN_29    = 5
-	PIN_30    = "Ground"
-	PIN_31    = 6
-	PIN_32    = 12
-	PIN_33    = 13
-	PIN_34    = "Ground"
-	PIN_35    = 19
-	PIN_36    = 16
-	PIN_37    = 26
-	PIN_38    = 20
-	PIN_39    = "Ground"
-	PIN_40    = 21
-	GPIOCount = 28
+// maxEpollEvents bounds how many ready FDs a single EpollWait call drains.
+// It's just a batch-size hint, not a limit on how many pins can be watched.
+const maxEpollEvents = 28
 
+const (
 	gpiobase     = "/sys/class/gpio"
 	exportPath   = gpiobase + "/export"
 	unexportPath = gpiobase + "/unexport"
@@ -65,68 +26,227 @@ var (
 	bytesClear = []byte{'0'}
 )
 
-// watchEventCallbacks is a map of pins and their callbacks when
-// watching for interrupts
-var watchEventCallbacks map[int]*pin
+// edgeWatcher is implemented by any backend pin that the shared epoll loop
+// below can deliver events to. ts is the time the triggering EpollWait call
+// returned, captured once per wake and shared by every FD it reports ready.
+type edgeWatcher interface {
+	fire(ts time.Time)
+}
+
+// epollWatcher owns the shared epoll instance used by every backend that
+// drives its watch via epoll, and the fd -> edgeWatcher registrations its
+// dispatch goroutine delivers events to. It is created lazily, on first
+// use, rather than at package init, so importing this package never spawns
+// a goroutine or touches the OS on its own.
+type epollWatcher struct {
+	fd int
+
+	// stopR/stopW are a self-pipe registered in the epoll set solely to
+	// wake run() out of its blocking EpollWait: closing fd does not do
+	// that on Linux, so Shutdown writes to stopW instead.
+	stopR, stopW int
+	done         chan struct{}
+
+	mu  sync.RWMutex
+	fds map[int]edgeWatcher
+}
 
-// epollFD is the FD for epoll
-var epollFD int
+var (
+	sharedWatcherMu sync.Mutex
+	sharedWatcher   *epollWatcher
+)
 
-func init() {
-	setupEpoll()
-	watchEventCallbacks = make(map[int]*pin)
+// Init eagerly creates the shared epoll instance used by epoll-backed
+// watches, returning any setup error immediately instead of deferring it to
+// the first BeginWatch call. It is optional: BeginWatch initializes the
+// watcher lazily if Init hasn't been called, and calling Init again once a
+// watcher already exists is a no-op.
+func Init() error {
+	_, err := sharedEpollWatcher()
+	return err
 }
 
-// setupEpoll sets up epoll for use
-func setupEpoll() {
-	var err error
-	epollFD, err = syscall.EpollCreate1(0)
+// Shutdown stops the shared epoll instance's dispatch goroutine and closes
+// its file descriptors, for tests and graceful reload. Callers should
+// EndWatch every open pin first; Shutdown does not do so itself. A later
+// BeginWatch call transparently creates a fresh watcher. Shutdown blocks
+// until the dispatch goroutine has actually returned.
+func Shutdown() error {
+	sharedWatcherMu.Lock()
+	w := sharedWatcher
+	sharedWatcher = nil
+	sharedWatcherMu.Unlock()
+
+	if w == nil {
+		return nil
+	}
+
+	// A blocked EpollWait isn't woken by closing its epoll fd, so nudge it
+	// via the self-pipe and wait for run() to notice and return before
+	// tearing anything down.
+	if _, err := syscall.Write(w.stopW, []byte{0}); err != nil {
+		return err
+	}
+	<-w.done
+
+	syscall.Close(w.stopR)
+	syscall.Close(w.stopW)
+	return syscall.Close(w.fd)
+}
+
+// sharedEpollWatcher returns the package's shared epoll watcher, creating
+// and starting it on first call.
+func sharedEpollWatcher() (*epollWatcher, error) {
+	sharedWatcherMu.Lock()
+	defer sharedWatcherMu.Unlock()
+
+	if sharedWatcher != nil {
+		return sharedWatcher, nil
+	}
+
+	fd, err := syscall.EpollCreate1(0)
 	if err != nil {
-		fmt.Println("Unable to create epoll FD: ", err.Error())
-		os.Exit(1)
+		return nil, fmt.Errorf("gpio: create epoll fd: %w", err)
 	}
 
-	go func() {
+	var stopFDs [2]int
+	if err := syscall.Pipe2(stopFDs[:], syscall.O_NONBLOCK); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("gpio: create shutdown pipe: %w", err)
+	}
+	stopEvent := syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(stopFDs[0])}
+	if err := syscall.EpollCtl(fd, syscall.EPOLL_CTL_ADD, stopFDs[0], &stopEvent); err != nil {
+		syscall.Close(stopFDs[0])
+		syscall.Close(stopFDs[1])
+		syscall.Close(fd)
+		return nil, fmt.Errorf("gpio: register shutdown pipe: %w", err)
+	}
 
-		var epollEvents [GPIOCount]syscall.EpollEvent
+	w := &epollWatcher{
+		fd:    fd,
+		stopR: stopFDs[0],
+		stopW: stopFDs[1],
+		done:  make(chan struct{}),
+		fds:   make(map[int]edgeWatcher),
+	}
+	go w.run()
+	sharedWatcher = w
+	return w, nil
+}
+
+// run drains ready FDs until the self-pipe wakes it for Shutdown,
+// dispatching each one to its registered edgeWatcher. w.fds is read under
+// w.mu since register/unregister can run concurrently from any pin's
+// BeginWatch or EndWatch.
+func (w *epollWatcher) run() {
+	defer close(w.done)
 
-		for {
-			numEvents, err := syscall.EpollWait(epollFD, epollEvents[:], -1)
-			if err != nil {
-				if err == syscall.EINTR || err == syscall.EAGAIN {
-					continue
-				}
-				panic(fmt.Sprintf("EpollWait error: %v", err))
+	var epollEvents [maxEpollEvents]syscall.EpollEvent
+
+	for {
+		numEvents, err := syscall.EpollWait(w.fd, epollEvents[:], -1)
+		if err != nil {
+			if err == syscall.EINTR || err == syscall.EAGAIN {
+				continue
 			}
-			for i := 0; i < numEvents; i++ {
-				if eventPin, exists := watchEventCallbacks[int(epollEvents[i].Fd)]; exists {
-					if eventPin.initial {
-						eventPin.initial = false
-					} else {
-						eventPin.callback()
-					}
-				}
+			return
+		}
+
+		ts := time.Now()
+		w.mu.RLock()
+		for i := 0; i < numEvents; i++ {
+			fd := int(epollEvents[i].Fd)
+			if fd == w.stopR {
+				w.mu.RUnlock()
+				return
+			}
+			if ew, exists := w.fds[fd]; exists {
+				ew.fire(ts)
 			}
 		}
+		w.mu.RUnlock()
+	}
+}
+
+// register adds fd to the epoll instance, associating it with ew.
+func (w *epollWatcher) register(fd int, ew edgeWatcher) error {
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		return err
+	}
 
-	}()
+	var event syscall.EpollEvent
+	event.Events = syscall.EPOLLIN | (syscall.EPOLLET & 0xffffffff) | syscall.EPOLLPRI
+	event.Fd = int32(fd)
+
+	if err := syscall.EpollCtl(w.fd, syscall.EPOLL_CTL_ADD, fd, &event); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.fds[fd] = ew
+	w.mu.Unlock()
+	return nil
 }
 
-// pin represents a GPIO pin.
-type pin struct {
-	number        int      // the pin number
-	numberAsBytes []byte   // the pin number as a byte array to avoid converting each time
-	modePath      string   // the path to the /direction FD to avoid string joining each time
-	edgePath      string   // the path to the /edge FD to avoid string joining each time
-	valueFile     *os.File // the file handle for the value file
-	callback      IRQEvent // the callback function to call when an interrupt occurs
-	initial       bool     // is this the initial epoll trigger?
-	err           error    //the last error
+// unregister removes fd from the epoll instance.
+func (w *epollWatcher) unregister(fd int) error {
+	if err := syscall.EpollCtl(w.fd, syscall.EPOLL_CTL_DEL, fd, nil); err != nil {
+		return err
+	}
+	if err := syscall.SetNonblock(fd, false); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	delete(w.fds, fd)
+	w.mu.Unlock()
+	return nil
+}
+
+// registerWatch adds fd to the shared epoll instance, associating it with w,
+// initializing the shared watcher first if this is the first watch.
+func registerWatch(fd int, w edgeWatcher) error {
+	ew, err := sharedEpollWatcher()
+	if err != nil {
+		return err
+	}
+	return ew.register(fd, w)
+}
+
+// unregisterWatch removes fd from the shared epoll instance. It is a no-op
+// if the shared watcher has already been shut down.
+func unregisterWatch(fd int) error {
+	sharedWatcherMu.Lock()
+	w := sharedWatcher
+	sharedWatcherMu.Unlock()
+
+	if w == nil {
+		return nil
+	}
+	return w.unregister(fd)
 }
 
-// OpenPin exports the pin, creating the virtual files necessary for interacting with the pin.
-// It also sets the mode for the pin, making it ready for use.
-func OpenPin(n int, mode Mode) (Pin, error) {
+// sysfsPin is a Pin backed by the legacy /sys/class/gpio ABI.
+type sysfsPin struct {
+	eventSink
+
+	number         int           // the pin number
+	numberAsBytes  []byte        // the pin number as a byte array to avoid converting each time
+	modePath       string        // the path to the /direction FD to avoid string joining each time
+	edgePath       string        // the path to the /edge FD to avoid string joining each time
+	valueFile      *os.File      // the file handle for the value file
+	callback       IRQEvent      // the callback function to call when an interrupt occurs
+	initial        bool          // is this the initial epoll trigger?
+	lastValue      bool          // the level seen by the previous fire, to work out edge direction
+	err            error         //the last error
+	watchMode      WatchMode     // how BeginWatch should deliver notifications
+	debouncePeriod time.Duration // passed to debounce() by BeginWatch
+	pollInterval   time.Duration // passed to pollWatch() by BeginWatch
+	stopPoll       func()        // set when BeginWatch fell back to polling
+}
+
+// openSysfsPin exports the pin, creating the virtual files necessary for
+// interacting with it, and applies cfg, making it ready for use.
+func openSysfsPin(n int, cfg PinConfig) (Pin, error) {
 	// export this pin to create the virtual files on the system
 	pinBase, err := expose(n)
 	if err != nil {
@@ -136,20 +256,89 @@ func OpenPin(n int, mode Mode) (Pin, error) {
 	if err != nil {
 		return nil, err
 	}
-	p := &pin{
-		number:    n,
-		modePath:  filepath.Join(pinBase, "direction"),
-		edgePath:  filepath.Join(pinBase, "edge"),
-		valueFile: value,
-		initial:   true,
+	p := &sysfsPin{
+		number:         n,
+		modePath:       filepath.Join(pinBase, "direction"),
+		edgePath:       filepath.Join(pinBase, "edge"),
+		valueFile:      value,
+		initial:        true,
+		watchMode:      cfg.WatchMode,
+		debouncePeriod: cfg.DebouncePeriod,
+		pollInterval:   cfg.PollInterval,
 	}
-	if err := p.setMode(mode); err != nil {
+	if err := p.applyConfig(pinBase, cfg); err != nil {
 		p.Close()
 		return nil, err
 	}
 	return p, nil
 }
 
+// applyConfig sets the pin's direction and, where the kernel's sysfs driver
+// exposes the corresponding attribute file, its active-low, bias and drive
+// settings. Not every sysfs gpio driver exposes bias/drive; those are
+// skipped rather than failing when absent.
+func (p *sysfsPin) applyConfig(pinBase string, cfg PinConfig) error {
+	if err := p.setMode(cfg.Mode); err != nil {
+		return err
+	}
+	if err := writeIfPresent(filepath.Join(pinBase, "active_low"), activeLowValue(cfg.ActiveLow)); err != nil {
+		return err
+	}
+	if v, ok := biasValue(cfg.Bias); ok {
+		if err := writeIfPresent(filepath.Join(pinBase, "bias"), v); err != nil {
+			return err
+		}
+	}
+	if cfg.Mode == ModeOutput {
+		if v, ok := driveValue(cfg.Drive); ok {
+			if err := writeIfPresent(filepath.Join(pinBase, "drive"), v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func activeLowValue(activeLow bool) []byte {
+	if activeLow {
+		return bytesSet
+	}
+	return bytesClear
+}
+
+func biasValue(bias Bias) ([]byte, bool) {
+	switch bias {
+	case BiasPullUp:
+		return []byte("pull-up"), true
+	case BiasPullDown:
+		return []byte("pull-down"), true
+	case BiasDisabled:
+		return []byte("disable"), true
+	}
+	return nil, false
+}
+
+func driveValue(drive Drive) ([]byte, bool) {
+	switch drive {
+	case DriveOpenDrain:
+		return []byte("open-drain"), true
+	case DriveOpenSource:
+		return []byte("open-source"), true
+	case DrivePushPull:
+		return []byte("push-pull"), true
+	}
+	return nil, false
+}
+
+// writeIfPresent writes buf to path, silently doing nothing if path does not
+// exist, since many sysfs gpio drivers don't expose every attribute.
+func writeIfPresent(path string, buf []byte) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	return write(buf, path)
+}
+
 // write opens a file for writing, writes the byte slice to it and closes the
 // file.
 func write(buf []byte, path string) error {
@@ -169,29 +358,29 @@ func read(path string) ([]byte, error) {
 }
 
 // Close destroys the virtual files on the filesystem, unexporting the pin.
-func (p *pin) Close() error {
+func (p *sysfsPin) Close() error {
 	return writeFile(filepath.Join(gpiobase, "unexport"), "%d", p.number)
 }
 
 // Mode retrieves the current mode of the pin.
-func (p *pin) Mode() Mode {
+func (p *sysfsPin) Mode() Mode {
 	var mode string
 	mode, p.err = readFile(p.modePath)
 	return Mode(mode)
 }
 
 // SetMode sets the mode of the pin.
-func (p *pin) SetMode(mode Mode) {
+func (p *sysfsPin) SetMode(mode Mode) {
 	p.err = p.setMode(mode)
 }
 
-func (p *pin) GetMode() Mode {
+func (p *sysfsPin) GetMode() Mode {
 	currentMode, _ := read(p.modePath)
 	currentMode_ := strings.Trim(string(currentMode), "\n ")
 	return Mode(currentMode_)
 }
 
-func (p *pin) setMode(mode Mode) error {
+func (p *sysfsPin) setMode(mode Mode) error {
 	if p.GetMode() != mode {
 		return write([]byte(mode), p.modePath)
 	} else {
@@ -200,84 +389,94 @@ func (p *pin) setMode(mode Mode) error {
 }
 
 // Set sets the pin level high.
-func (p *pin) Set() {
+func (p *sysfsPin) Set() {
 	_, p.err = p.valueFile.Write(bytesSet)
 }
 
 // Clear sets the pin level low.
-func (p *pin) Clear() {
+func (p *sysfsPin) Clear() {
 	_, p.err = p.valueFile.Write(bytesClear)
 }
 
 // Get retrieves the current pin level.
-func (p *pin) Get() bool {
+func (p *sysfsPin) Get() bool {
 	bytes := make([]byte, 1)
 	_, p.err = p.valueFile.ReadAt(bytes, 0)
 	return bytes[0] == bytesSet[0]
 }
 
+// fire implements edgeWatcher. The very first epoll wake after registering
+// just reports the current state, so it is swallowed here.
+func (p *sysfsPin) fire(ts time.Time) {
+	current := p.Get()
+	if p.initial {
+		p.initial = false
+		p.lastValue = current
+		return
+	}
+	rising := current && !p.lastValue
+	p.lastValue = current
+	edge := EdgeFalling
+	if rising {
+		edge = EdgeRising
+	}
+	p.deliver(p.callback, Event{Pin: p.number, Edge: edge, Time: ts, Seq: p.nextSeq()})
+}
+
 // Watch waits for the edge level to be triggered and then calls the callback
 // Watch sets the pin mode to input on your behalf, then establishes the interrupt on
 // the edge provided
 
-func (p *pin) BeginWatch(edge Edge, callback IRQEvent) error {
-	if p.GetMode() != ModeInput {
-		fmt.Printf("Error BeginWatch: pin input mode is not \"IN\" %+v", p)
-		panic("Error BeginWatch: pin input mode is not correct")
+func (p *sysfsPin) BeginWatch(edge Edge, callback IRQEvent) error {
+	if mode := p.GetMode(); mode != ModeInput {
+		return fmt.Errorf("gpio: BeginWatch requires input mode, pin %d is %s", p.number, mode)
 	}
-	//p.SetMode(ModeInput)
 	if err := write([]byte(edge), p.edgePath); err != nil {
 		return err
 	}
 
-	var event syscall.EpollEvent
-	event.Events = syscall.EPOLLIN | (syscall.EPOLLET & 0xffffffff) | syscall.EPOLLPRI
-
-	fd := int(p.valueFile.Fd())
-
-	p.callback = callback
-	watchEventCallbacks[fd] = p
+	callback = debounce(p.debouncePeriod, callback)
 
-	if err := syscall.SetNonblock(fd, true); err != nil {
-		return err
+	if p.watchMode == WatchPoll {
+		p.stopPoll = pollWatch(p.number, edge, p.pollInterval, p.Get, func(ev Event) {
+			ev.Seq = p.nextSeq()
+			p.deliver(callback, ev)
+		})
+		return nil
 	}
 
-	event.Fd = int32(fd)
-
-	if err := syscall.EpollCtl(epollFD, syscall.EPOLL_CTL_ADD, fd, &event); err != nil {
-		return err
+	p.callback = callback
+	if err := registerWatch(int(p.valueFile.Fd()), p); err != nil {
+		if p.watchMode != WatchAuto {
+			return err
+		}
+		// This kernel/driver doesn't deliver poll() notifications on
+		// value; fall back to polling.
+		p.stopPoll = pollWatch(p.number, edge, p.pollInterval, p.Get, func(ev Event) {
+			ev.Seq = p.nextSeq()
+			p.deliver(callback, ev)
+		})
 	}
-
 	return nil
-
 }
 
 // EndWatch stops watching the pin
-func (p *pin) EndWatch() error {
-
-	fd := int(p.valueFile.Fd())
-
-	if err := syscall.EpollCtl(epollFD, syscall.EPOLL_CTL_DEL, fd, nil); err != nil {
-		return err
-	}
-
-	if err := syscall.SetNonblock(fd, false); err != nil {
-		return err
+func (p *sysfsPin) EndWatch() error {
+	if p.stopPoll != nil {
+		p.stopPoll()
+		p.stopPoll = nil
+		return nil
 	}
-
-	delete(watchEventCallbacks, fd)
-
-	return nil
-
+	return unregisterWatch(int(p.valueFile.Fd()))
 }
 
 // Wait blocks while waits for the pin state to match the condition, then returns.
-func (p *pin) Wait(condition bool) {
+func (p *sysfsPin) Wait(condition bool) {
 	panic("Wait is not yet implemented!")
 }
 
 // Err returns the last error encountered.
-func (p *pin) Err() error {
+func (p *sysfsPin) Err() error {
 	return p.err
 }
 
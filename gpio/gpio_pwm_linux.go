@@ -0,0 +1,58 @@
+package gpio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const pwmBase = "/sys/class/pwm"
+
+// sysfsPWMPin is a PWMPin backed by /sys/class/pwm/pwmchipN.
+type sysfsPWMPin struct {
+	chip        int
+	channel     int
+	channelPath string
+}
+
+// openSysfsPWM exports channel on pwmchipN, creating the virtual files
+// necessary for driving it.
+func openSysfsPWM(chip, channel int) (PWMPin, error) {
+	chipPath := filepath.Join(pwmBase, fmt.Sprintf("pwmchip%d", chip))
+	channelPath := filepath.Join(chipPath, fmt.Sprintf("pwm%d", channel))
+
+	if _, err := os.Stat(channelPath); os.IsNotExist(err) {
+		if err := writeFile(filepath.Join(chipPath, "export"), "%d", channel); err != nil {
+			return nil, err
+		}
+	}
+
+	return &sysfsPWMPin{chip: chip, channel: channel, channelPath: channelPath}, nil
+}
+
+// SetPeriod sets the total period of the PWM waveform.
+func (p *sysfsPWMPin) SetPeriod(period time.Duration) error {
+	return writeFile(filepath.Join(p.channelPath, "period"), "%d", period.Nanoseconds())
+}
+
+// SetDuty sets how much of each period the signal stays high.
+func (p *sysfsPWMPin) SetDuty(duty time.Duration) error {
+	return writeFile(filepath.Join(p.channelPath, "duty_cycle"), "%d", duty.Nanoseconds())
+}
+
+// Enable starts the PWM output.
+func (p *sysfsPWMPin) Enable() error {
+	return writeFile(filepath.Join(p.channelPath, "enable"), "%d", 1)
+}
+
+// Disable stops the PWM output.
+func (p *sysfsPWMPin) Disable() error {
+	return writeFile(filepath.Join(p.channelPath, "enable"), "%d", 0)
+}
+
+// Close unexports the channel.
+func (p *sysfsPWMPin) Close() error {
+	chipPath := filepath.Join(pwmBase, fmt.Sprintf("pwmchip%d", p.chip))
+	return writeFile(filepath.Join(chipPath, "unexport"), "%d", p.channel)
+}
@@ -0,0 +1,130 @@
+package gpio
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultPollInterval is used by WatchPoll/WatchAuto when
+// PinConfig.PollInterval is zero.
+const defaultPollInterval = 10 * time.Millisecond
+
+// eventBufferSize bounds the channel returned by eventSink.Events.
+const eventBufferSize = 16
+
+// eventSink gives a backend pin the bookkeeping shared by BeginWatch and
+// Events: a per-watch sequence number and the lazily-created Events()
+// channel. Backend pins embed it to satisfy Pin's Events method.
+type eventSink struct {
+	mu     sync.Mutex
+	seq    uint64
+	events chan Event
+}
+
+// nextSeq returns the next sequence number for this pin's watch.
+func (s *eventSink) nextSeq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return s.seq
+}
+
+// Events implements Pin.
+func (s *eventSink) Events() <-chan Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events == nil {
+		s.events = make(chan Event, eventBufferSize)
+	}
+	return s.events
+}
+
+// deliver calls callback with ev and, if Events has been called, publishes
+// ev to its channel, dropping the oldest buffered event if the consumer is
+// behind. Events always sees the raw, un-debounced edge; debounce only
+// affects the callback passed to BeginWatch.
+func (s *eventSink) deliver(callback IRQEvent, ev Event) {
+	callback(ev)
+
+	s.mu.Lock()
+	ch := s.events
+	s.mu.Unlock()
+	if ch == nil {
+		return
+	}
+	for {
+		select {
+		case ch <- ev:
+			return
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+		}
+	}
+}
+
+// debounce wraps callback so it only fires once the pin has been quiet for
+// period: edges arriving within period of the previous one reset the timer
+// instead of firing immediately, and the callback receives the most recent
+// event. A zero period returns callback unchanged.
+func debounce(period time.Duration, callback IRQEvent) IRQEvent {
+	if period <= 0 {
+		return callback
+	}
+
+	var mu sync.Mutex
+	var timer *time.Timer
+
+	return func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(period, func() { callback(ev) })
+	}
+}
+
+// pollWatch starts a goroutine that polls get every interval and calls
+// deliver whenever a transition matching edge is observed. It's the
+// fallback for platforms/kernels that don't deliver poll() notifications on
+// a pin's value. The returned func stops the goroutine and should be called
+// from EndWatch.
+func pollWatch(number int, edge Edge, interval time.Duration, get func() bool, deliver func(Event)) func() {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := get()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				current := get()
+				if current == last {
+					continue
+				}
+				rising := current && !last
+				last = current
+				if edge == EdgeNone || (edge != EdgeBoth && ((edge == EdgeRising) != rising)) {
+					continue
+				}
+				e := EdgeFalling
+				if rising {
+					e = EdgeRising
+				}
+				deliver(Event{Pin: number, Edge: e, Time: time.Now()})
+			}
+		}
+	}()
+
+	return func() { close(stop) }
+}